@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// singleDeadline is one independently re-armable deadline: every arm call
+// replaces the previous timer and hands back a fresh cancel channel, so
+// re-arming while one is already pending can't race with a time.AfterFunc
+// firing between attempts - callers always wait on the channel returned by
+// whichever arm call is currently in flight.
+type singleDeadline struct {
+	mu     sync.Mutex
+	timer  *time.Timer
+	cancel chan struct{}
+}
+
+// arm sets t as the new deadline and returns the channel that will be
+// closed when it expires. A zero time.Time disarms the deadline; the
+// returned channel is then never closed.
+func (s *singleDeadline) arm(t time.Time) chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+
+	c := make(chan struct{})
+	s.cancel = c
+	if !t.IsZero() {
+		s.timer = time.AfterFunc(time.Until(t), func() {
+			close(c)
+		})
+	}
+	return c
+}
+
+// channel returns the cancel channel handed out by the most recent arm call,
+// or nil if arm has never been called. After arm with a zero time.Time it
+// is a channel that is never closed.
+func (s *singleDeadline) channel() chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cancel
+}
+
+// deadlineTimer holds a ComfyClient's read and write deadlines. They are
+// tracked independently - arming one never clobbers the other - but both
+// apply to the whole request: whichever fires first cancels it, in its
+// write phase or its read phase alike.
+type deadlineTimer struct {
+	read  singleDeadline
+	write singleDeadline
+}
+
+// SetReadDeadline arms the read deadline, independently of any write
+// deadline. When it fires it cancels the whole request, not only the
+// reading of the response.
+func (d *deadlineTimer) SetReadDeadline(t time.Time) {
+	d.read.arm(t)
+}
+
+// SetWriteDeadline arms the write deadline, independently of any read
+// deadline. When it fires it cancels the whole request, not only the
+// sending of the body.
+func (d *deadlineTimer) SetWriteDeadline(t time.Time) {
+	d.write.arm(t)
+}
+
+// withDeadline returns a context that is cancelled when ctx is done, or
+// when either the read or the write deadline (whichever is armed and
+// expires first) fires. The deadlines are read once, here: a deadline armed
+// or re-armed after this call does not apply to the returned context, and
+// re-arming stops the timer that this context was waiting on. The returned
+// cancel func must be called once the request completes to release
+// resources.
+func (d *deadlineTimer) withDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	readCh := d.read.channel()
+	writeCh := d.write.channel()
+	if readCh == nil && writeCh == nil {
+		return ctx, func() {}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-readCh:
+		case <-writeCh:
+		case <-ctx.Done():
+		}
+		cancel()
+	}()
+	return ctx, cancel
+}
@@ -0,0 +1,105 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSingleDeadlineExpires(t *testing.T) {
+	var s singleDeadline
+	cancelCh := s.arm(time.Now().Add(10 * time.Millisecond))
+
+	select {
+	case <-cancelCh:
+	case <-time.After(time.Second):
+		t.Fatal("deadline did not expire in time")
+	}
+}
+
+func TestSingleDeadlineDisarm(t *testing.T) {
+	var s singleDeadline
+	cancelCh := s.arm(time.Time{})
+
+	select {
+	case <-cancelCh:
+		t.Fatal("zero-value deadline should never expire")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestSingleDeadlineReArm(t *testing.T) {
+	var s singleDeadline
+	first := s.arm(time.Now().Add(time.Hour))
+	second := s.arm(time.Now().Add(10 * time.Millisecond))
+
+	select {
+	case <-first:
+		t.Fatal("re-arming the deadline should not close the previous channel")
+	default:
+	}
+
+	select {
+	case <-second:
+	case <-time.After(time.Second):
+		t.Fatal("re-armed deadline did not expire in time")
+	}
+}
+
+func TestDeadlineTimerReadAndWriteAreIndependent(t *testing.T) {
+	var d deadlineTimer
+	readCh := d.read.arm(time.Now().Add(time.Hour))
+	d.SetWriteDeadline(time.Now().Add(10 * time.Millisecond))
+
+	select {
+	case <-readCh:
+		t.Fatal("arming the write deadline should not affect the read deadline")
+	default:
+	}
+
+	select {
+	case <-d.write.channel():
+	case <-time.After(time.Second):
+		t.Fatal("write deadline did not expire in time")
+	}
+}
+
+func TestDeadlineTimerWithDeadlineCancelsContextOnRead(t *testing.T) {
+	var d deadlineTimer
+	d.SetReadDeadline(time.Now().Add(10 * time.Millisecond))
+
+	ctx, cancel := d.withDeadline(context.Background())
+	defer cancel()
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("context was not cancelled when the read deadline expired")
+	}
+}
+
+func TestDeadlineTimerWithDeadlineCancelsContextOnWrite(t *testing.T) {
+	var d deadlineTimer
+	d.SetWriteDeadline(time.Now().Add(10 * time.Millisecond))
+
+	ctx, cancel := d.withDeadline(context.Background())
+	defer cancel()
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("context was not cancelled when the write deadline expired")
+	}
+}
+
+func TestDeadlineTimerWithDeadlineNoop(t *testing.T) {
+	var d deadlineTimer
+	parent := context.Background()
+
+	ctx, cancel := d.withDeadline(parent)
+	defer cancel()
+
+	if ctx != parent {
+		t.Error("withDeadline should return the parent context unchanged when no deadline is armed")
+	}
+}
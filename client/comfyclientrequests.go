@@ -1,6 +1,8 @@
 package client
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -36,15 +38,126 @@ import (
 @routes.post("/upload/mask")
 */
 
+// makeRequest issues a request with no caller-supplied context. It exists
+// for backwards compatibility with code written before context support was
+// added; deadlines armed on the client and the retry policy still apply.
 func (c *ComfyClient) makeRequest(method string, path string, header http.Header, body io.Reader) (*http.Response, error) {
+	return c.makeRequestContext(context.Background(), method, path, header, body)
+}
+
+// makeRequestContext is the context-aware counterpart to makeRequest. The
+// request is cancelled the moment ctx is done, and, independently, the
+// moment a deadline that was armed via SetReadDeadline/SetWriteDeadline
+// when the call began expires - so a stuck ComfyUI server can no longer
+// hang the caller forever. A deadline armed or re-armed while the call is
+// in flight does not apply to it.
+func (c *ComfyClient) makeRequestContext(ctx context.Context, method string, path string, header http.Header, body io.Reader) (*http.Response, error) {
+	// Merge the caller's context with the client's deadline, if one is armed.
+	ctx, cancel := c.deadline.withDeadline(ctx)
+	defer cancel()
+
+	// body can only be replayed across retry attempts if we buffer it up
+	// front; non-idempotent callers (POST /prompt, POST /upload/*) make a
+	// single attempt in the loop below, so this only runs for safe, small
+	// bodies.
+	var bodyBytes []byte
+	if body != nil && c.isRetryable(method, path) {
+		var err error
+		bodyBytes, err = io.ReadAll(body)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	policy := c.retryPolicy
+	if policy.MaxAttempts == 0 {
+		policy = DefaultRetryPolicy()
+	}
+	attempts := 1
+	if c.isRetryable(method, path) && policy.MaxAttempts > attempts {
+		attempts = policy.MaxAttempts
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			if err := sleep(ctx, policy.backoffInterval(attempt-1)); err != nil {
+				return nil, err
+			}
+		}
+
+		var reqBody io.Reader
+		if bodyBytes != nil {
+			reqBody = bytes.NewReader(bodyBytes)
+		} else {
+			reqBody = body
+		}
+
+		resp, err := c.doRequest(ctx, method, path, header, reqBody)
+		if attempt == attempts-1 || !isTransient(resp, err) {
+			return resp, err
+		}
+		// About to retry: drain and close this response so its connection
+		// can be returned to the client's pool instead of leaking.
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// doRequest builds and executes a single HTTP request; it performs no
+// retries of its own, save for the one-shot re-auth-and-retry a
+// refreshTokenAuthProvider gets on a 401.
+func (c *ComfyClient) doRequest(ctx context.Context, method string, path string, header http.Header, body io.Reader) (*http.Response, error) {
+	refresher, canRetryAuth := c.authProvider.(*refreshTokenAuthProvider)
+
+	// Only a refreshTokenAuthProvider ever triggers the 401-retry below, so
+	// only then do we pay the cost of buffering the body for replay. Every
+	// other caller - including the io.Pipe-backed body from
+	// NewImageUpload/NewMaskUpload - gets body streamed straight through.
+	var bodyBytes []byte
+	if body != nil && canRetryAuth {
+		var err error
+		bodyBytes, err = io.ReadAll(body)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(bodyBytes)
+	}
+
+	resp, err := c.doRequestOnce(ctx, method, path, header, body)
+	if err != nil {
+		return resp, err
+	}
+
+	if canRetryAuth && resp.StatusCode == http.StatusUnauthorized {
+		resp.Body.Close()
+		if err := refresher.invalidateAndRefresh(ctx); err != nil {
+			return nil, err
+		}
+		return c.doRequestOnce(ctx, method, path, header, bytes.NewReader(bodyBytes))
+	}
+
+	return resp, nil
+}
+
+func (c *ComfyClient) doRequestOnce(ctx context.Context, method string, path string, header http.Header, body io.Reader) (*http.Response, error) {
 	// Create a new request
-	req, err := http.NewRequest(method, fmt.Sprintf("%s/%s", c.serverBaseAddress, path), body)
+	req, err := http.NewRequestWithContext(ctx, method, fmt.Sprintf("%s/%s", c.serverBaseAddress, path), body)
 	if err != nil {
 		return nil, err
 	}
 
-	// Add default Authorization header if needed
-	if c.authHeader != "" {
+	// Apply auth: a pluggable AuthProvider takes precedence over the
+	// legacy static Authorization header.
+	if c.authProvider != nil {
+		if err := c.authProvider.Apply(req); err != nil {
+			return nil, err
+		}
+	} else if c.authHeader != "" {
 		req.Header.Add("Authorization", c.authHeader)
 	}
 
@@ -57,9 +170,9 @@ func (c *ComfyClient) makeRequest(method string, path string, header http.Header
 		}
 	}
 
-	// Create a client and execute the request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	// Execute the request against the client's shared http.Client, so
+	// connections are pooled and kept alive across calls.
+	resp, err := c.httpClientOrDefault().Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -68,12 +181,16 @@ func (c *ComfyClient) makeRequest(method string, path string, header http.Header
 }
 
 func (c *ComfyClient) GetSystemStats() (*SystemStats, error) {
+	return c.GetSystemStatsContext(context.Background())
+}
+
+func (c *ComfyClient) GetSystemStatsContext(ctx context.Context) (*SystemStats, error) {
 	err := c.CheckConnection()
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := c.makeRequest("GET", "system_stats", nil, nil)
+	resp, err := c.makeRequestContext(ctx, "GET", "system_stats", nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -89,7 +206,11 @@ func (c *ComfyClient) GetSystemStats() (*SystemStats, error) {
 }
 
 func (c *ComfyClient) GetPromptHistoryByIndex() ([]PromptHistoryItem, error) {
-	history, err := c.GetPromptHistoryByID()
+	return c.GetPromptHistoryByIndexContext(context.Background())
+}
+
+func (c *ComfyClient) GetPromptHistoryByIndexContext(ctx context.Context) ([]PromptHistoryItem, error) {
+	history, err := c.GetPromptHistoryByIDContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -112,7 +233,11 @@ func (c *ComfyClient) GetPromptHistoryByIndex() ([]PromptHistoryItem, error) {
 }
 
 func (c *ComfyClient) GetPromptHistoryByID() (map[string]PromptHistoryItem, error) {
-	resp, err := c.makeRequest("GET", "history", nil, nil)
+	return c.GetPromptHistoryByIDContext(context.Background())
+}
+
+func (c *ComfyClient) GetPromptHistoryByIDContext(ctx context.Context) (map[string]PromptHistoryItem, error) {
+	resp, err := c.makeRequestContext(ctx, "GET", "history", nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -197,8 +322,12 @@ func (c *ComfyClient) GetPromptHistoryByID() (map[string]PromptHistoryItem, erro
 // onnx
 // fonts
 func (c *ComfyClient) GetViewMetadata(folder string, file string) (string, error) {
+	return c.GetViewMetadataContext(context.Background(), folder, file)
+}
+
+func (c *ComfyClient) GetViewMetadataContext(ctx context.Context, folder string, file string) (string, error) {
 	path := fmt.Sprintf("view_metadata/%s?filename=%s", folder, file)
-	resp, err := c.makeRequest("GET", path, nil, nil)
+	resp, err := c.makeRequestContext(ctx, "GET", path, nil, nil)
 	if err != nil {
 		return "", err
 	}
@@ -209,6 +338,10 @@ func (c *ComfyClient) GetViewMetadata(folder string, file string) (string, error
 
 // GetImage
 func (c *ComfyClient) GetImage(image_data DataOutput) (*[]byte, error) {
+	return c.GetImageContext(context.Background(), image_data)
+}
+
+func (c *ComfyClient) GetImageContext(ctx context.Context, image_data DataOutput) (*[]byte, error) {
 	u, err := url.Parse(fmt.Sprintf("%s/view", c.serverBaseAddress))
 	if err != nil {
 		return nil, err
@@ -220,7 +353,7 @@ func (c *ComfyClient) GetImage(image_data DataOutput) (*[]byte, error) {
 	u.RawQuery = q.Encode()
 
 	// Make the request
-	resp, err := c.makeRequest("GET", u.String(), nil, nil)
+	resp, err := c.makeRequestContext(ctx, "GET", u.String(), nil, nil)
 
 	if err != nil {
 		return nil, err
@@ -232,7 +365,11 @@ func (c *ComfyClient) GetImage(image_data DataOutput) (*[]byte, error) {
 
 // GetEmbeddings retrieves the list of Embeddings models installed on the ComfyUI server.
 func (c *ComfyClient) GetEmbeddings() ([]string, error) {
-	resp, err := c.makeRequest("GET", "embeddings", nil, nil)
+	return c.GetEmbeddingsContext(context.Background())
+}
+
+func (c *ComfyClient) GetEmbeddingsContext(ctx context.Context) ([]string, error) {
+	resp, err := c.makeRequestContext(ctx, "GET", "embeddings", nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -248,7 +385,11 @@ func (c *ComfyClient) GetEmbeddings() ([]string, error) {
 }
 
 func (c *ComfyClient) GetQueueExecutionInfo() (*QueueExecInfo, error) {
-	resp, err := c.makeRequest("GET", "prompt", nil, nil)
+	return c.GetQueueExecutionInfoContext(context.Background())
+}
+
+func (c *ComfyClient) GetQueueExecutionInfoContext(ctx context.Context) (*QueueExecInfo, error) {
+	resp, err := c.makeRequestContext(ctx, "GET", "prompt", nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -265,7 +406,11 @@ func (c *ComfyClient) GetQueueExecutionInfo() (*QueueExecInfo, error) {
 
 // GetExtensions retrieves the list of extensions installed on the ComfyUI server.
 func (c *ComfyClient) GetExtensions() ([]string, error) {
-	resp, err := c.makeRequest("GET", "extensions", nil, nil)
+	return c.GetExtensionsContext(context.Background())
+}
+
+func (c *ComfyClient) GetExtensionsContext(ctx context.Context) ([]string, error) {
+	resp, err := c.makeRequestContext(ctx, "GET", "extensions", nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -281,7 +426,11 @@ func (c *ComfyClient) GetExtensions() ([]string, error) {
 }
 
 func (c *ComfyClient) GetObjectInfos() (*graphapi.NodeObjects, error) {
-	resp, err := c.makeRequest("GET", "object_info", nil, nil)
+	return c.GetObjectInfosContext(context.Background())
+}
+
+func (c *ComfyClient) GetObjectInfosContext(ctx context.Context) (*graphapi.NodeObjects, error) {
+	resp, err := c.makeRequestContext(ctx, "GET", "object_info", nil, nil)
 
 	if err != nil {
 		return nil, err
@@ -299,6 +448,10 @@ func (c *ComfyClient) GetObjectInfos() (*graphapi.NodeObjects, error) {
 }
 
 func (c *ComfyClient) QueuePrompt(graph *graphapi.Graph) (*QueueItem, error) {
+	return c.QueuePromptContext(context.Background(), graph)
+}
+
+func (c *ComfyClient) QueuePromptContext(ctx context.Context, graph *graphapi.Graph) (*QueueItem, error) {
 	err := c.CheckConnection()
 	if err != nil {
 		return nil, err
@@ -319,7 +472,7 @@ func (c *ComfyClient) QueuePrompt(graph *graphapi.Graph) (*QueueItem, error) {
 	// Make the POST request using makeRequest
 	header := http.Header{}
 	header.Add("Content-Type", "application/json") // Set Content-Type header
-	resp, err := c.makeRequest("POST", "prompt", header, strings.NewReader(string(data)))
+	resp, err := c.makeRequestContext(ctx, "POST", "prompt", header, strings.NewReader(string(data)))
 
 	if err != nil {
 		return nil, err
@@ -358,10 +511,14 @@ func (c *ComfyClient) QueuePrompt(graph *graphapi.Graph) (*QueueItem, error) {
 }
 
 func (c *ComfyClient) Interrupt() error {
+	return c.InterruptContext(context.Background())
+}
+
+func (c *ComfyClient) InterruptContext(ctx context.Context) error {
 	// Make the POST request using makeRequest
 	header := http.Header{}
 	header.Add("Content-Type", "application/json")
-	resp, err := c.makeRequest("POST", "interrupt", header, strings.NewReader("{}"))
+	resp, err := c.makeRequestContext(ctx, "POST", "interrupt", header, strings.NewReader("{}"))
 	if err != nil {
 		return err
 	}
@@ -371,13 +528,17 @@ func (c *ComfyClient) Interrupt() error {
 }
 
 func (c *ComfyClient) EraseHistory() error {
+	return c.EraseHistoryContext(context.Background())
+}
+
+func (c *ComfyClient) EraseHistoryContext(ctx context.Context) error {
 	// Create the data
 	data := "{\"clear\": \"clear\"}"
 
 	// Make the POST request using makeRequest
 	header := http.Header{}
 	header.Add("Content-Type", "application/json")
-	resp, err := c.makeRequest("POST", "history", header, strings.NewReader(data))
+	resp, err := c.makeRequestContext(ctx, "POST", "history", header, strings.NewReader(data))
 	if err != nil {
 		return err
 	}
@@ -387,13 +548,17 @@ func (c *ComfyClient) EraseHistory() error {
 }
 
 func (c *ComfyClient) EraseHistoryItem(promptID string) error {
+	return c.EraseHistoryItemContext(context.Background(), promptID)
+}
+
+func (c *ComfyClient) EraseHistoryItemContext(ctx context.Context, promptID string) error {
 	// Create the data
 	item := fmt.Sprintf("{\"delete\": [\"%s\"]}", promptID)
 
 	// Make the POST request using makeRequest
 	header := http.Header{}
 	header.Add("Content-Type", "application/json")
-	resp, err := c.makeRequest("POST", "history", header, strings.NewReader(item))
+	resp, err := c.makeRequestContext(ctx, "POST", "history", header, strings.NewReader(item))
 	if err != nil {
 		return err
 	}
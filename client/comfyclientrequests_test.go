@@ -0,0 +1,103 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// trackedBody wraps a response body and records whether Close was called,
+// so the test can assert the retry loop doesn't leak it.
+type trackedBody struct {
+	io.ReadCloser
+	mu     *sync.Mutex
+	closed *bool
+}
+
+func (b trackedBody) Close() error {
+	b.mu.Lock()
+	*b.closed = true
+	b.mu.Unlock()
+	return b.ReadCloser.Close()
+}
+
+// trackingTransport wraps http.DefaultTransport and records whether each
+// response body it hands back was later closed.
+type trackingTransport struct {
+	mu      sync.Mutex
+	closed  []bool
+	wrapped http.RoundTripper
+}
+
+func (t *trackingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.wrapped.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+
+	t.mu.Lock()
+	idx := len(t.closed)
+	t.closed = append(t.closed, false)
+	t.mu.Unlock()
+
+	resp.Body = trackedBody{ReadCloser: resp.Body, mu: &t.mu, closed: &t.closed[idx]}
+	return resp, nil
+}
+
+func (t *trackingTransport) allClosed() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, c := range t.closed {
+		if !c {
+			return false
+		}
+	}
+	return true
+}
+
+func TestMakeRequestContextClosesTransientResponseBodies(t *testing.T) {
+	attempt := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempt++
+		if attempt == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte("unavailable"))
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	transport := &trackingTransport{wrapped: http.DefaultTransport}
+	c := &ComfyClient{
+		serverBaseAddress: srv.URL,
+		httpClient:        &http.Client{Transport: transport},
+		retryPolicy: RetryPolicy{
+			MaxAttempts:     2,
+			InitialInterval: time.Millisecond,
+			Multiplier:      1,
+			MaxInterval:     time.Millisecond,
+		},
+	}
+
+	resp, err := c.makeRequestContext(context.Background(), http.MethodGet, "system_stats", nil, nil)
+	if err != nil {
+		t.Fatalf("makeRequestContext: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "ok" {
+		t.Fatalf("final body = %q, want %q", body, "ok")
+	}
+
+	if attempt != 2 {
+		t.Fatalf("server saw %d attempts, want 2", attempt)
+	}
+	if !transport.allClosed() {
+		t.Error("the transient 503 response body was never closed before retrying")
+	}
+}
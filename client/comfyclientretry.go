@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// ComfyClientOption configures a ComfyClient at construction time.
+type ComfyClientOption func(*ComfyClient)
+
+// RetryPolicy controls how makeRequestContext retries idempotent requests:
+// an initial interval that grows by Multiplier on each attempt, capped at
+// MaxInterval, with up to Jitter fractional random variance applied to
+// avoid retry storms.
+type RetryPolicy struct {
+	MaxAttempts     int
+	InitialInterval time.Duration
+	Multiplier      float64
+	MaxInterval     time.Duration
+	Jitter          float64
+}
+
+// DefaultRetryPolicy is a conservative policy suitable for history/object_info
+// polling: a handful of attempts with a quick initial backoff.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:     3,
+		InitialInterval: 250 * time.Millisecond,
+		Multiplier:      2.0,
+		MaxInterval:     5 * time.Second,
+		Jitter:          0.2,
+	}
+}
+
+// WithRetryPolicy configures the RetryPolicy used for idempotent requests
+// made through this client.
+func WithRetryPolicy(policy RetryPolicy) ComfyClientOption {
+	return func(c *ComfyClient) {
+		c.retryPolicy = policy
+	}
+}
+
+// retryableMethods are the verbs makeRequestContext is allowed to retry.
+// POST /prompt and POST /upload/* are not included: they are not
+// idempotent, and retrying them risks double-queuing a prompt or
+// duplicating an upload.
+var retryableMethods = map[string]bool{
+	http.MethodGet: true,
+}
+
+// retryablePaths lists the non-GET endpoints that are safe to retry because
+// they are naturally idempotent (interrupting an already-interrupted queue,
+// or clearing/deleting history that's already gone, are both no-ops).
+var retryablePaths = map[string]bool{
+	"interrupt": true,
+	"history":   true,
+}
+
+func (c *ComfyClient) isRetryable(method string, path string) bool {
+	if retryableMethods[method] {
+		return true
+	}
+	return method == http.MethodPost && retryablePaths[path]
+}
+
+// isTransient reports whether err or resp represents a failure worth
+// retrying: a connection-level error, or a 502/503/504 response.
+func isTransient(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// backoffInterval returns the delay before retry attempt n (0-indexed),
+// applying the policy's multiplier, cap, and jitter.
+func (p RetryPolicy) backoffInterval(attempt int) time.Duration {
+	interval := float64(p.InitialInterval) * math.Pow(p.Multiplier, float64(attempt))
+	if max := float64(p.MaxInterval); p.MaxInterval > 0 && interval > max {
+		interval = max
+	}
+	if p.Jitter > 0 {
+		interval += interval * p.Jitter * (rand.Float64()*2 - 1)
+	}
+	if interval < 0 {
+		interval = 0
+	}
+	return time.Duration(interval)
+}
+
+// sleep waits out the backoff interval d, returning ctx.Err() if
+// ctx is cancelled first so retries cooperate with caller deadlines.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
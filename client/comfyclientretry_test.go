@@ -0,0 +1,100 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyBackoffInterval(t *testing.T) {
+	policy := RetryPolicy{
+		InitialInterval: 100 * time.Millisecond,
+		Multiplier:      2.0,
+		MaxInterval:     300 * time.Millisecond,
+		Jitter:          0,
+	}
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 0, want: 100 * time.Millisecond},
+		{attempt: 1, want: 200 * time.Millisecond},
+		{attempt: 2, want: 300 * time.Millisecond}, // would be 400ms, clamped to MaxInterval
+		{attempt: 5, want: 300 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		if got := policy.backoffInterval(tt.attempt); got != tt.want {
+			t.Errorf("backoffInterval(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestRetryPolicyBackoffIntervalJitter(t *testing.T) {
+	policy := RetryPolicy{
+		InitialInterval: 100 * time.Millisecond,
+		Multiplier:      1.0,
+		MaxInterval:     time.Second,
+		Jitter:          0.2,
+	}
+
+	// jitter must keep the interval within InitialInterval +/- 20%
+	min := 80 * time.Millisecond
+	max := 120 * time.Millisecond
+	for i := 0; i < 50; i++ {
+		got := policy.backoffInterval(0)
+		if got < min || got > max {
+			t.Fatalf("backoffInterval with jitter = %v, want within [%v, %v]", got, min, max)
+		}
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	c := &ComfyClient{}
+
+	tests := []struct {
+		method string
+		path   string
+		want   bool
+	}{
+		{method: http.MethodGet, path: "system_stats", want: true},
+		{method: http.MethodGet, path: "history", want: true},
+		{method: http.MethodPost, path: "interrupt", want: true},
+		{method: http.MethodPost, path: "history", want: true},
+		{method: http.MethodPost, path: "prompt", want: false},
+		{method: http.MethodPost, path: "upload/image", want: false},
+		{method: http.MethodPatch, path: "interrupt", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := c.isRetryable(tt.method, tt.path); got != tt.want {
+			t.Errorf("isRetryable(%q, %q) = %v, want %v", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		resp *http.Response
+		err  error
+		want bool
+	}{
+		{name: "connection error", resp: nil, err: errors.New("dial tcp: connection refused"), want: true},
+		{name: "502", resp: &http.Response{StatusCode: http.StatusBadGateway}, want: true},
+		{name: "503", resp: &http.Response{StatusCode: http.StatusServiceUnavailable}, want: true},
+		{name: "504", resp: &http.Response{StatusCode: http.StatusGatewayTimeout}, want: true},
+		{name: "200", resp: &http.Response{StatusCode: http.StatusOK}, want: false},
+		{name: "404", resp: &http.Response{StatusCode: http.StatusNotFound}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTransient(tt.resp, tt.err); got != tt.want {
+				t.Errorf("isTransient() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/GentlemanHu/comfy2go/graphapi"
+)
+
+// jsonStreamFormatter writes newline-delimited JSON progress records,
+// flushing after each one so a caller piping it straight to an HTTP
+// response, a log, or a CLI TTY sees updates as they happen: one method per
+// event kind, one JSON object per line.
+type jsonStreamFormatter struct {
+	w *bufio.Writer
+}
+
+func newJSONStreamFormatter(w io.Writer) *jsonStreamFormatter {
+	return &jsonStreamFormatter{w: bufio.NewWriter(w)}
+}
+
+type streamProgress struct {
+	Value int `json:"value"`
+	Max   int `json:"max"`
+}
+
+type streamRecord struct {
+	Status   string               `json:"status"`
+	PromptID string               `json:"promptID,omitempty"`
+	Node     string               `json:"node,omitempty"`
+	Progress *streamProgress      `json:"progress,omitempty"`
+	Message  string               `json:"message,omitempty"`
+	Outputs  map[int][]DataOutput `json:"outputs,omitempty"`
+}
+
+func (f *jsonStreamFormatter) writeRecord(r streamRecord) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if _, err := f.w.Write(data); err != nil {
+		return err
+	}
+	if err := f.w.WriteByte('\n'); err != nil {
+		return err
+	}
+	return f.w.Flush()
+}
+
+// FormatStatus writes a {"status":"queued","promptID":...} style record.
+func (f *jsonStreamFormatter) FormatStatus(status string, promptID string) error {
+	return f.writeRecord(streamRecord{Status: status, PromptID: promptID})
+}
+
+// FormatProgress writes a {"status":"executing","node":...,"progress":{...}} record.
+func (f *jsonStreamFormatter) FormatProgress(node string, value int, max int) error {
+	return f.writeRecord(streamRecord{
+		Status:   "executing",
+		Node:     node,
+		Progress: &streamProgress{Value: value, Max: max},
+	})
+}
+
+// FormatError writes a {"status":"error","message":...} record.
+func (f *jsonStreamFormatter) FormatError(err error) error {
+	return f.writeRecord(streamRecord{Status: "error", Message: err.Error()})
+}
+
+// FormatComplete writes a {"status":"complete","outputs":{...}} record.
+func (f *jsonStreamFormatter) FormatComplete(outputs map[int][]DataOutput) error {
+	return f.writeRecord(streamRecord{Status: "complete", Outputs: outputs})
+}
+
+// QueuePromptStream queues graph and writes newline-delimited JSON progress
+// records to w as PromptMessages arrive on the returned QueueItem, so
+// callers don't have to poll item.Messages themselves. It returns once the
+// prompt has either completed or errored; the QueueItem is also returned so
+// callers can still inspect it afterwards.
+func (c *ComfyClient) QueuePromptStream(graph *graphapi.Graph, w io.Writer) (*QueueItem, error) {
+	return c.QueuePromptStreamContext(context.Background(), graph, w)
+}
+
+func (c *ComfyClient) QueuePromptStreamContext(ctx context.Context, graph *graphapi.Graph, w io.Writer) (*QueueItem, error) {
+	item, err := c.QueuePromptContext(ctx, graph)
+	if err != nil {
+		return nil, err
+	}
+
+	formatter := newJSONStreamFormatter(w)
+	if err := formatter.FormatStatus("queued", item.PromptID); err != nil {
+		return item, err
+	}
+
+	return item, streamPromptMessages(ctx, item.Messages, formatter)
+}
+
+// streamPromptMessages formats each PromptMessage from messages until the
+// prompt completes, errors, the channel closes, or ctx is done - whichever
+// comes first - so a stuck ComfyUI server can't hang the caller forever.
+func streamPromptMessages(ctx context.Context, messages <-chan PromptMessage, formatter *jsonStreamFormatter) error {
+	for {
+		select {
+		case <-ctx.Done():
+			formatter.FormatError(ctx.Err())
+			return ctx.Err()
+		case msg, ok := <-messages:
+			if !ok {
+				return nil
+			}
+			switch msg.Type {
+			case "executing":
+				if err := formatter.FormatProgress(msg.Node, 0, 0); err != nil {
+					return err
+				}
+			case "progress":
+				if err := formatter.FormatProgress(msg.Node, msg.Value, msg.Max); err != nil {
+					return err
+				}
+			case "execution_error":
+				execErr := errors.New(msg.Message)
+				if err := formatter.FormatError(execErr); err != nil {
+					return err
+				}
+				return execErr
+			case "execution_success":
+				return formatter.FormatComplete(nil)
+			}
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJSONStreamFormatterRecordShapes(t *testing.T) {
+	var buf bytes.Buffer
+	f := newJSONStreamFormatter(&buf)
+
+	if err := f.FormatStatus("queued", "abc123"); err != nil {
+		t.Fatalf("FormatStatus: %v", err)
+	}
+	if err := f.FormatProgress("KSampler", 7, 20); err != nil {
+		t.Fatalf("FormatProgress: %v", err)
+	}
+	if err := f.FormatError(errors.New("boom")); err != nil {
+		t.Fatalf("FormatError: %v", err)
+	}
+	if err := f.FormatComplete(nil); err != nil {
+		t.Fatalf("FormatComplete: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("got %d lines, want 4: %q", len(lines), lines)
+	}
+
+	var status streamRecord
+	if err := json.Unmarshal([]byte(lines[0]), &status); err != nil {
+		t.Fatalf("unmarshal status record: %v", err)
+	}
+	if status.Status != "queued" || status.PromptID != "abc123" {
+		t.Errorf("status record = %+v, want status=queued promptID=abc123", status)
+	}
+
+	var progress streamRecord
+	if err := json.Unmarshal([]byte(lines[1]), &progress); err != nil {
+		t.Fatalf("unmarshal progress record: %v", err)
+	}
+	if progress.Status != "executing" || progress.Node != "KSampler" || progress.Progress == nil {
+		t.Fatalf("progress record = %+v, want status=executing node=KSampler with progress", progress)
+	}
+	if progress.Progress.Value != 7 || progress.Progress.Max != 20 {
+		t.Errorf("progress = %+v, want value=7 max=20", progress.Progress)
+	}
+
+	var errRec streamRecord
+	if err := json.Unmarshal([]byte(lines[2]), &errRec); err != nil {
+		t.Fatalf("unmarshal error record: %v", err)
+	}
+	if errRec.Status != "error" || errRec.Message != "boom" {
+		t.Errorf("error record = %+v, want status=error message=boom", errRec)
+	}
+
+	var completeRec streamRecord
+	if err := json.Unmarshal([]byte(lines[3]), &completeRec); err != nil {
+		t.Fatalf("unmarshal complete record: %v", err)
+	}
+	if completeRec.Status != "complete" {
+		t.Errorf("complete record = %+v, want status=complete", completeRec)
+	}
+}
+
+func TestStreamPromptMessagesStopsOnContextDone(t *testing.T) {
+	var buf bytes.Buffer
+	formatter := newJSONStreamFormatter(&buf)
+	messages := make(chan PromptMessage) // never written to, never closed
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- streamPromptMessages(ctx, messages, formatter)
+	}()
+
+	select {
+	case err := <-errCh:
+		if err != context.DeadlineExceeded {
+			t.Errorf("err = %v, want %v", err, context.DeadlineExceeded)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("streamPromptMessages did not return once its context expired")
+	}
+}
+
+func TestStreamPromptMessagesReturnsOnExecutionError(t *testing.T) {
+	var buf bytes.Buffer
+	formatter := newJSONStreamFormatter(&buf)
+	messages := make(chan PromptMessage, 1)
+	messages <- PromptMessage{Type: "execution_error", Message: "boom"}
+
+	err := streamPromptMessages(context.Background(), messages, formatter)
+	if err == nil || err.Error() != "boom" {
+		t.Errorf("err = %v, want an error wrapping %q", err, "boom")
+	}
+}
+
+func TestStreamPromptMessagesReturnsOnChannelClose(t *testing.T) {
+	var buf bytes.Buffer
+	formatter := newJSONStreamFormatter(&buf)
+	messages := make(chan PromptMessage)
+	close(messages)
+
+	if err := streamPromptMessages(context.Background(), messages, formatter); err != nil {
+		t.Errorf("err = %v, want nil on channel close", err)
+	}
+}
+
+func TestJSONStreamFormatterFlushesEachLine(t *testing.T) {
+	var buf bytes.Buffer
+	f := newJSONStreamFormatter(&buf)
+
+	if err := f.FormatStatus("queued", "p1"); err != nil {
+		t.Fatalf("FormatStatus: %v", err)
+	}
+	if buf.Len() == 0 {
+		t.Error("expected data to be flushed to the underlying writer immediately")
+	}
+}
@@ -0,0 +1,137 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// AuthProvider applies authentication to an outgoing request. It supersedes
+// the single static c.authHeader field, allowing dynamic auth schemes -
+// token refresh, HMAC signing, OIDC - in addition to the built-in static
+// implementations below.
+type AuthProvider interface {
+	Apply(req *http.Request) error
+}
+
+// WithHTTPClient lets callers supply their own *http.Client - e.g. one with
+// a custom http.Transport for connection pooling, TLS configuration, or
+// proxying through a Cloudflare Access / RunPod-style reverse proxy.
+func WithHTTPClient(hc *http.Client) ComfyClientOption {
+	return func(c *ComfyClient) {
+		c.httpClient = hc
+	}
+}
+
+// WithAuthProvider configures the AuthProvider applied to every outgoing
+// request, in place of the static c.authHeader.
+func WithAuthProvider(p AuthProvider) ComfyClientOption {
+	return func(c *ComfyClient) {
+		c.authProvider = p
+	}
+}
+
+// defaultHTTPClient is used when a ComfyClient isn't given one via
+// WithHTTPClient, so repeated requests still reuse a single keep-alive
+// transport instead of paying a fresh TCP/TLS handshake each time.
+var defaultHTTPClient = &http.Client{}
+
+func (c *ComfyClient) httpClientOrDefault() *http.Client {
+	if c.httpClient != nil {
+		return c.httpClient
+	}
+	return defaultHTTPClient
+}
+
+// bearerAuthProvider applies a static "Authorization: Bearer <token>" header.
+type bearerAuthProvider struct {
+	token string
+}
+
+// NewBearerAuthProvider returns an AuthProvider that sets a static bearer
+// token on every request.
+func NewBearerAuthProvider(token string) AuthProvider {
+	return &bearerAuthProvider{token: token}
+}
+
+func (p *bearerAuthProvider) Apply(req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+p.token)
+	return nil
+}
+
+// basicAuthProvider applies HTTP basic auth.
+type basicAuthProvider struct {
+	username string
+	password string
+}
+
+// NewBasicAuthProvider returns an AuthProvider that sets HTTP basic auth
+// credentials on every request.
+func NewBasicAuthProvider(username string, password string) AuthProvider {
+	return &basicAuthProvider{username: username, password: password}
+}
+
+func (p *basicAuthProvider) Apply(req *http.Request) error {
+	req.SetBasicAuth(p.username, p.password)
+	return nil
+}
+
+// RefreshFunc fetches a new bearer token, e.g. from an OIDC token endpoint.
+type RefreshFunc func(ctx context.Context) (string, error)
+
+// refreshTokenAuthProvider applies a bearer token obtained from refresh,
+// re-fetching it lazily on first use and whenever makeRequestContext sees a
+// 401 and retries.
+type refreshTokenAuthProvider struct {
+	refresh RefreshFunc
+
+	mu    sync.Mutex
+	token string
+}
+
+// NewRefreshTokenAuthProvider returns an AuthProvider backed by a
+// short-lived bearer token that refresh re-fetches on demand. It's intended
+// for hosted ComfyUI deployments behind auth proxies with short-lived
+// tokens, where a static Authorization header would expire mid-session.
+func NewRefreshTokenAuthProvider(refresh RefreshFunc) AuthProvider {
+	return &refreshTokenAuthProvider{refresh: refresh}
+}
+
+func (p *refreshTokenAuthProvider) Apply(req *http.Request) error {
+	p.mu.Lock()
+	token := p.token
+	p.mu.Unlock()
+
+	if token == "" {
+		if err := p.refreshToken(req.Context()); err != nil {
+			return err
+		}
+		p.mu.Lock()
+		token = p.token
+		p.mu.Unlock()
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// invalidateAndRefresh discards the cached token and fetches a new one; it
+// is called when a request comes back 401, so the retry uses a fresh token.
+func (p *refreshTokenAuthProvider) invalidateAndRefresh(ctx context.Context) error {
+	p.mu.Lock()
+	p.token = ""
+	p.mu.Unlock()
+	return p.refreshToken(ctx)
+}
+
+func (p *refreshTokenAuthProvider) refreshToken(ctx context.Context) error {
+	token, err := p.refresh(ctx)
+	if err != nil {
+		return fmt.Errorf("refreshing auth token: %w", err)
+	}
+	p.mu.Lock()
+	p.token = token
+	p.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,110 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestBearerAuthProviderApply(t *testing.T) {
+	p := NewBearerAuthProvider("tok123")
+	req, _ := http.NewRequest(http.MethodGet, "http://example.invalid", nil)
+
+	if err := p.Apply(req); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if got := req.Header.Get("Authorization"); got != "Bearer tok123" {
+		t.Errorf("Authorization header = %q, want %q", got, "Bearer tok123")
+	}
+}
+
+func TestBasicAuthProviderApply(t *testing.T) {
+	p := NewBasicAuthProvider("alice", "hunter2")
+	req, _ := http.NewRequest(http.MethodGet, "http://example.invalid", nil)
+
+	if err := p.Apply(req); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	user, pass, ok := req.BasicAuth()
+	if !ok || user != "alice" || pass != "hunter2" {
+		t.Errorf("BasicAuth() = (%q, %q, %v), want (alice, hunter2, true)", user, pass, ok)
+	}
+}
+
+func TestRefreshTokenAuthProviderFetchesOnce(t *testing.T) {
+	calls := 0
+	p := NewRefreshTokenAuthProvider(func(ctx context.Context) (string, error) {
+		calls++
+		return "tok-v1", nil
+	}).(*refreshTokenAuthProvider)
+
+	for i := 0; i < 3; i++ {
+		req, _ := http.NewRequest(http.MethodGet, "http://example.invalid", nil)
+		if err := p.Apply(req); err != nil {
+			t.Fatalf("Apply: %v", err)
+		}
+		if got := req.Header.Get("Authorization"); got != "Bearer tok-v1" {
+			t.Errorf("Authorization header = %q, want %q", got, "Bearer tok-v1")
+		}
+	}
+
+	if calls != 1 {
+		t.Errorf("refresh func called %d times, want 1 (token should be cached)", calls)
+	}
+}
+
+func TestRefreshTokenAuthProviderInvalidateAndRefresh(t *testing.T) {
+	tokens := []string{"tok-v1", "tok-v2"}
+	call := 0
+	p := NewRefreshTokenAuthProvider(func(ctx context.Context) (string, error) {
+		tok := tokens[call]
+		call++
+		return tok, nil
+	}).(*refreshTokenAuthProvider)
+
+	req, _ := http.NewRequest(http.MethodGet, "http://example.invalid", nil)
+	if err := p.Apply(req); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if got := req.Header.Get("Authorization"); got != "Bearer tok-v1" {
+		t.Fatalf("Authorization header = %q, want %q", got, "Bearer tok-v1")
+	}
+
+	if err := p.invalidateAndRefresh(context.Background()); err != nil {
+		t.Fatalf("invalidateAndRefresh: %v", err)
+	}
+
+	req2, _ := http.NewRequest(http.MethodGet, "http://example.invalid", nil)
+	if err := p.Apply(req2); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if got := req2.Header.Get("Authorization"); got != "Bearer tok-v2" {
+		t.Errorf("Authorization header after refresh = %q, want %q", got, "Bearer tok-v2")
+	}
+}
+
+func TestRefreshTokenAuthProviderPropagatesError(t *testing.T) {
+	wantErr := errors.New("token endpoint unreachable")
+	p := NewRefreshTokenAuthProvider(func(ctx context.Context) (string, error) {
+		return "", wantErr
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "http://example.invalid", nil)
+	if err := p.Apply(req); err == nil {
+		t.Fatal("expected Apply to propagate the refresh error")
+	}
+}
+
+func TestHTTPClientOrDefault(t *testing.T) {
+	c := &ComfyClient{}
+	if c.httpClientOrDefault() != defaultHTTPClient {
+		t.Error("expected the shared default http.Client when none is configured")
+	}
+
+	custom := &http.Client{}
+	WithHTTPClient(custom)(c)
+	if c.httpClientOrDefault() != custom {
+		t.Error("expected WithHTTPClient's client to take precedence")
+	}
+}
@@ -0,0 +1,184 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+)
+
+// UploadedImage is the response ComfyUI gives back for a successful
+// /upload/image or /upload/mask call.
+type UploadedImage struct {
+	Name      string `json:"name"`
+	Subfolder string `json:"subfolder"`
+	Type      string `json:"type"`
+}
+
+// UploadImage uploads the contents of r to the server's input folder under
+// name. If overwrite is false and a file of the same name already exists,
+// ComfyUI will rename the upload rather than replace it.
+func (c *ComfyClient) UploadImage(name string, r io.Reader, overwrite bool, subfolder string) (*UploadedImage, error) {
+	return c.UploadImageContext(context.Background(), name, r, overwrite, subfolder)
+}
+
+func (c *ComfyClient) UploadImageContext(ctx context.Context, name string, r io.Reader, overwrite bool, subfolder string) (*UploadedImage, error) {
+	return c.uploadContext(ctx, "upload/image", "image", name, r, overwrite, subfolder)
+}
+
+// UploadMask uploads a mask image the same way UploadImage does.
+func (c *ComfyClient) UploadMask(name string, r io.Reader, overwrite bool, subfolder string) (*UploadedImage, error) {
+	return c.UploadMaskContext(context.Background(), name, r, overwrite, subfolder)
+}
+
+func (c *ComfyClient) UploadMaskContext(ctx context.Context, name string, r io.Reader, overwrite bool, subfolder string) (*UploadedImage, error) {
+	return c.uploadContext(ctx, "upload/mask", "image", name, r, overwrite, subfolder)
+}
+
+// uploadContext performs the single multipart/form-data POST that ComfyUI's
+// /upload/image and /upload/mask routes actually support - ComfyUI has no
+// chunked or resumable upload protocol, so this is the only wire format
+// available regardless of file size. For large animatediff/video frame
+// sets or controlnet inputs, prefer NewImageUpload, which streams whatever
+// is written to it straight into the same kind of POST instead of
+// buffering it.
+func (c *ComfyClient) uploadContext(ctx context.Context, path string, field string, name string, r io.Reader, overwrite bool, subfolder string) (*UploadedImage, error) {
+	err := c.CheckConnection()
+	if err != nil {
+		return nil, err
+	}
+
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+
+	part, err := w.CreateFormFile(field, name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(part, r); err != nil {
+		return nil, err
+	}
+	if err := w.WriteField("overwrite", strconv.FormatBool(overwrite)); err != nil {
+		return nil, err
+	}
+	if subfolder != "" {
+		if err := w.WriteField("subfolder", subfolder); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	header := http.Header{}
+	header.Add("Content-Type", w.FormDataContentType())
+	resp, err := c.makeRequestContext(ctx, "POST", path, header, body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &UploadedImage{}
+	if err := json.Unmarshal(respBody, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// pipeUpload is a streaming writer for /upload/image and /upload/mask. It
+// feeds Write calls straight into the multipart body of a single in-flight
+// POST via an io.Pipe, so a large animatediff/video frame or controlnet
+// input never has to be buffered whole before the upload starts. ComfyUI
+// has no resumable or chunked-request upload protocol - this is the one
+// POST multipart/form-data wire format its /upload/image and /upload/mask
+// routes support, regardless of input size.
+type pipeUpload struct {
+	pw   *io.PipeWriter
+	mw   *multipart.Writer
+	part io.Writer
+	done chan error
+}
+
+// NewImageUpload returns an io.WriteCloser that streams whatever is written
+// to it into a single POST to /upload/image, without buffering the whole
+// input in memory first. Callers should Write in any size they like and
+// Close once done; Close finalizes the multipart body and waits for the
+// upload to complete.
+func (c *ComfyClient) NewImageUpload(name string, overwrite bool, subfolder string) (io.WriteCloser, error) {
+	return c.newPipeUploadContext(context.Background(), "upload/image", name, overwrite, subfolder)
+}
+
+// NewMaskUpload is the mask-endpoint counterpart to NewImageUpload.
+func (c *ComfyClient) NewMaskUpload(name string, overwrite bool, subfolder string) (io.WriteCloser, error) {
+	return c.newPipeUploadContext(context.Background(), "upload/mask", name, overwrite, subfolder)
+}
+
+func (c *ComfyClient) newPipeUploadContext(ctx context.Context, path string, name string, overwrite bool, subfolder string) (io.WriteCloser, error) {
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+
+	done := make(chan error, 1)
+	go func() {
+		header := http.Header{}
+		header.Add("Content-Type", mw.FormDataContentType())
+		resp, err := c.makeRequestContext(ctx, "POST", path, header, pr)
+		if err != nil {
+			pr.CloseWithError(err)
+			done <- err
+			return
+		}
+		defer resp.Body.Close()
+		io.Copy(io.Discard, resp.Body)
+		if resp.StatusCode >= 400 {
+			done <- fmt.Errorf("upload to %s failed with status %d", path, resp.StatusCode)
+			return
+		}
+		done <- nil
+	}()
+
+	// These writes block until the goroutine above has dispatched the POST
+	// and its transport starts reading pr, which is the standard way to
+	// stream a multipart body into an in-flight request.
+	if err := mw.WriteField("overwrite", strconv.FormatBool(overwrite)); err != nil {
+		pw.Close()
+		return nil, err
+	}
+	if subfolder != "" {
+		if err := mw.WriteField("subfolder", subfolder); err != nil {
+			pw.Close()
+			return nil, err
+		}
+	}
+	part, err := mw.CreateFormFile("image", name)
+	if err != nil {
+		pw.Close()
+		return nil, err
+	}
+
+	return &pipeUpload{pw: pw, mw: mw, part: part, done: done}, nil
+}
+
+func (u *pipeUpload) Write(p []byte) (int, error) {
+	return u.part.Write(p)
+}
+
+func (u *pipeUpload) Close() error {
+	if err := u.mw.Close(); err != nil {
+		u.pw.CloseWithError(err)
+		<-u.done
+		return err
+	}
+	if err := u.pw.Close(); err != nil {
+		return err
+	}
+	return <-u.done
+}
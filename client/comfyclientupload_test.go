@@ -0,0 +1,109 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestNewImageUploadStreamsWithoutFullBuffering guards against the body
+// being read into memory before the request to the server has even
+// started, which would defeat the point of the streaming upload writer.
+func TestNewImageUploadStreamsWithoutFullBuffering(t *testing.T) {
+	serverReading := make(chan struct{})
+	releaseServer := make(chan struct{})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(serverReading)
+		<-releaseServer
+		io.Copy(io.Discard, r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":"big.bin","subfolder":"","type":"input"}`))
+	}))
+	defer srv.Close()
+
+	c := &ComfyClient{serverBaseAddress: srv.URL}
+	upload, err := c.NewImageUpload("big.bin", false, "")
+	if err != nil {
+		t.Fatalf("NewImageUpload: %v", err)
+	}
+
+	payload := make([]byte, 20*1024*1024) // larger than any OS socket buffer
+	writeDone := make(chan error, 1)
+	go func() {
+		_, err := upload.Write(payload)
+		writeDone <- err
+	}()
+
+	select {
+	case <-serverReading:
+	case <-time.After(time.Second):
+		t.Fatal("server never observed the request starting")
+	}
+
+	// The handler hasn't read anything yet, so a streaming writer should
+	// still be blocked on pipe/socket backpressure here.
+	select {
+	case err := <-writeDone:
+		t.Fatalf("Write completed (err=%v) before the server read any data - payload was fully buffered instead of streamed", err)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	close(releaseServer)
+
+	select {
+	case err := <-writeDone:
+		if err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("write did not complete once the server started reading")
+	}
+
+	if err := upload.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+}
+
+func TestUploadImageSendsMultipartFields(t *testing.T) {
+	var gotOverwrite, gotSubfolder, gotFilename string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("ParseMultipartForm: %v", err)
+		}
+		gotOverwrite = r.FormValue("overwrite")
+		gotSubfolder = r.FormValue("subfolder")
+		if fh := r.MultipartForm.File["image"]; len(fh) == 1 {
+			gotFilename = fh[0].Filename
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":"file.png","subfolder":"clips","type":"input"}`))
+	}))
+	defer srv.Close()
+
+	c := &ComfyClient{serverBaseAddress: srv.URL}
+	result, err := c.UploadImage("file.png", emptyReader{}, true, "clips")
+	if err != nil {
+		t.Fatalf("UploadImage: %v", err)
+	}
+
+	if gotOverwrite != "true" {
+		t.Errorf("overwrite field = %q, want %q", gotOverwrite, "true")
+	}
+	if gotSubfolder != "clips" {
+		t.Errorf("subfolder field = %q, want %q", gotSubfolder, "clips")
+	}
+	if gotFilename != "file.png" {
+		t.Errorf("filename = %q, want %q", gotFilename, "file.png")
+	}
+	if result.Name != "file.png" || result.Subfolder != "clips" {
+		t.Errorf("result = %+v, want name=file.png subfolder=clips", result)
+	}
+}
+
+type emptyReader struct{}
+
+func (emptyReader) Read(p []byte) (int, error) { return 0, io.EOF }